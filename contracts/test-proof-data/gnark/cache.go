@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+)
+
+// dumpMeta records the platform a cached dump was produced on, plus (for
+// PLONK) the provenance of the SRS that produced it. gnark's
+// WriteDump/ReadDump format is raw and platform-dependent (no subgroup
+// checks, native endianness), so a dump from a different GOOS/GOARCH can't
+// be safely loaded back; similarly a cache entry set up against one SRS
+// source must not be silently served once the caller asks for a different
+// one (e.g. switching from the unsafe dev SRS to a real --ptau file).
+type dumpMeta struct {
+	GOOS   string `json:"goos"`
+	GOARCH string `json:"goarch"`
+	// SRSSource is one of "unsafe", "provided" (raw SRS bytes over the
+	// wire), or "ptau:<sha256 of the ceremony file>". Empty for Groth16,
+	// which has no SRS.
+	SRSSource string `json:"srs_source,omitempty"`
+}
+
+func currentDumpMeta(srsSource string) dumpMeta {
+	return dumpMeta{GOOS: runtime.GOOS, GOARCH: runtime.GOARCH, SRSSource: srsSource}
+}
+
+// srsProvenance reports which SRS source a PLONK setup/prove call will use,
+// in the same precedence order as handlePlonk/runSetupCommand: wire-provided
+// bytes, then a ptau ceremony file (tagged with its content hash so
+// swapping in a different file invalidates the cache), then the unsafe
+// development SRS. With empty srsBytes/srsLagrangeBytes/ptauPath it returns
+// "unsafe"; Groth16 has no SRS, does not call this, and caches with "".
+func srsProvenance(srsBytes, srsLagrangeBytes []byte, ptauPath string) (string, error) {
+	switch {
+	case len(srsBytes) > 0 && len(srsLagrangeBytes) > 0:
+		return "provided", nil
+	case ptauPath != "":
+		hash, err := ptauFileHash(ptauPath)
+		if err != nil {
+			return "", err
+		}
+		return "ptau:" + hash, nil
+	default:
+		return "unsafe", nil
+	}
+}
+
+// checkDumpMeta verifies a cached dump was produced on this platform and
+// (when wantSRSSource is non-empty) from the SRS source being requested now.
+func checkDumpMeta(cacheDir, id, wantSRSSource string) error {
+	data, err := os.ReadFile(metaPath(cacheDir, id))
+	if err != nil {
+		return fmt.Errorf("failed to read dump metadata: %v", err)
+	}
+	var meta dumpMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return fmt.Errorf("failed to parse dump metadata: %v", err)
+	}
+	want := currentDumpMeta(wantSRSSource)
+	if meta.GOOS != want.GOOS || meta.GOARCH != want.GOARCH {
+		return fmt.Errorf("refusing to load pk-cache dump for %s: produced on %s/%s, running on %s/%s", id, meta.GOOS, meta.GOARCH, want.GOOS, want.GOARCH)
+	}
+	if wantSRSSource != "" && meta.SRSSource != wantSRSSource {
+		return fmt.Errorf("refusing to load pk-cache dump for %s: cached SRS source %q does not match requested %q", id, meta.SRSSource, wantSRSSource)
+	}
+	return nil
+}
+
+func writeDumpMeta(cacheDir, id, srsSource string) error {
+	data, err := json.Marshal(currentDumpMeta(srsSource))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(metaPath(cacheDir, id), data, 0o644)
+}
+
+func metaPath(cacheDir, id string) string    { return filepath.Join(cacheDir, id+".meta.json") }
+func pkDumpPath(cacheDir, id string) string  { return filepath.Join(cacheDir, id+".pk.dump") }
+func vkPath(cacheDir, id string) string      { return filepath.Join(cacheDir, id+".vk") }
+func srsDumpPath(cacheDir, id string) string { return filepath.Join(cacheDir, id+".srs.dump") }
+func srsLagrangeDumpPath(cacheDir, id string) string {
+	return filepath.Join(cacheDir, id+".srs-lagrange.dump")
+}
+
+// dumper/undumper mirror gnark's unsafe Dump fast path: raw, no subgroup
+// checks, native-endian. Orders of magnitude faster than WriteTo/ReadFrom
+// for multi-hundred-MB proving keys and SRSes.
+type dumper interface {
+	WriteDump(w io.Writer) error
+}
+type undumper interface {
+	ReadDump(r io.Reader) error
+}
+
+func writeDump(path string, v dumper) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return v.WriteDump(f)
+}
+
+func readDump(path string, v undumper) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return v.ReadDump(f)
+}
+
+// loadGroth16Cache returns ok=false (no error) on a plain cache miss, and a
+// non-nil error only when cached files exist but can't be trusted or used.
+func loadGroth16Cache(cacheDir, id string, curveID ecc.ID) (pk groth16.ProvingKey, vk groth16.VerifyingKey, ok bool, err error) {
+	if cacheDir == "" {
+		return nil, nil, false, nil
+	}
+	if _, statErr := os.Stat(pkDumpPath(cacheDir, id)); statErr != nil {
+		return nil, nil, false, nil
+	}
+	if err := checkDumpMeta(cacheDir, id, ""); err != nil {
+		return nil, nil, false, err
+	}
+
+	pk = groth16.NewProvingKey(curveID)
+	pkUndumper, ok := pk.(undumper)
+	if !ok {
+		return nil, nil, false, fmt.Errorf("groth16 proving key does not support the dump format")
+	}
+	if err := readDump(pkDumpPath(cacheDir, id), pkUndumper); err != nil {
+		return nil, nil, false, fmt.Errorf("failed to read cached proving key: %v", err)
+	}
+
+	vk = groth16.NewVerifyingKey(curveID)
+	vkData, err := os.ReadFile(vkPath(cacheDir, id))
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to read cached verifying key: %v", err)
+	}
+	if _, err := vk.ReadFrom(bytes.NewReader(vkData)); err != nil {
+		return nil, nil, false, fmt.Errorf("failed to parse cached verifying key: %v", err)
+	}
+
+	return pk, vk, true, nil
+}
+
+func saveGroth16Cache(cacheDir, id string, pk groth16.ProvingKey, vk groth16.VerifyingKey) error {
+	if cacheDir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create pk-cache-dir: %v", err)
+	}
+
+	pkDumper, ok := pk.(dumper)
+	if !ok {
+		return fmt.Errorf("groth16 proving key does not support the dump format")
+	}
+	if err := writeDump(pkDumpPath(cacheDir, id), pkDumper); err != nil {
+		return fmt.Errorf("failed to write cached proving key: %v", err)
+	}
+
+	var vkBuf bytes.Buffer
+	if _, err := vk.WriteTo(&vkBuf); err != nil {
+		return fmt.Errorf("failed to serialize verifying key: %v", err)
+	}
+	if err := os.WriteFile(vkPath(cacheDir, id), vkBuf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write cached verifying key: %v", err)
+	}
+
+	return writeDumpMeta(cacheDir, id, "")
+}
+
+// loadPlonkCache mirrors loadGroth16Cache, additionally caching the SRS and
+// SRS-Lagrange bases so a Powers-of-Tau file only needs to be supplied once.
+// wantSRSSource (see srsProvenance) must match the SRS source the cache
+// entry was built from, or the cache is refused the same way a GOOS/GOARCH
+// mismatch is.
+func loadPlonkCache(cacheDir, id string, curveID ecc.ID, wantSRSSource string) (pk plonk.ProvingKey, vk plonk.VerifyingKey, srs, srsLagrange kzg.SRS, ok bool, err error) {
+	if cacheDir == "" {
+		return nil, nil, nil, nil, false, nil
+	}
+	if _, statErr := os.Stat(pkDumpPath(cacheDir, id)); statErr != nil {
+		return nil, nil, nil, nil, false, nil
+	}
+	if err := checkDumpMeta(cacheDir, id, wantSRSSource); err != nil {
+		return nil, nil, nil, nil, false, err
+	}
+
+	pk = plonk.NewProvingKey(curveID)
+	pkUndumper, ok := pk.(undumper)
+	if !ok {
+		return nil, nil, nil, nil, false, fmt.Errorf("plonk proving key does not support the dump format")
+	}
+	if err := readDump(pkDumpPath(cacheDir, id), pkUndumper); err != nil {
+		return nil, nil, nil, nil, false, fmt.Errorf("failed to read cached proving key: %v", err)
+	}
+
+	vk = plonk.NewVerifyingKey(curveID)
+	vkData, err := os.ReadFile(vkPath(cacheDir, id))
+	if err != nil {
+		return nil, nil, nil, nil, false, fmt.Errorf("failed to read cached verifying key: %v", err)
+	}
+	if _, err := vk.ReadFrom(bytes.NewReader(vkData)); err != nil {
+		return nil, nil, nil, nil, false, fmt.Errorf("failed to parse cached verifying key: %v", err)
+	}
+
+	srs = kzg.NewSRS(curveID)
+	srsUndumper, ok := srs.(undumper)
+	if !ok {
+		return nil, nil, nil, nil, false, fmt.Errorf("kzg SRS does not support the dump format")
+	}
+	if err := readDump(srsDumpPath(cacheDir, id), srsUndumper); err != nil {
+		return nil, nil, nil, nil, false, fmt.Errorf("failed to read cached SRS: %v", err)
+	}
+
+	srsLagrange = kzg.NewSRS(curveID)
+	srsLagrangeUndumper, ok := srsLagrange.(undumper)
+	if !ok {
+		return nil, nil, nil, nil, false, fmt.Errorf("kzg SRS does not support the dump format")
+	}
+	if err := readDump(srsLagrangeDumpPath(cacheDir, id), srsLagrangeUndumper); err != nil {
+		return nil, nil, nil, nil, false, fmt.Errorf("failed to read cached SRS Lagrange: %v", err)
+	}
+
+	return pk, vk, srs, srsLagrange, true, nil
+}
+
+func savePlonkCache(cacheDir, id string, pk plonk.ProvingKey, vk plonk.VerifyingKey, srs, srsLagrange kzg.SRS, srsSource string) error {
+	if cacheDir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create pk-cache-dir: %v", err)
+	}
+
+	pkDumper, ok := pk.(dumper)
+	if !ok {
+		return fmt.Errorf("plonk proving key does not support the dump format")
+	}
+	if err := writeDump(pkDumpPath(cacheDir, id), pkDumper); err != nil {
+		return fmt.Errorf("failed to write cached proving key: %v", err)
+	}
+
+	var vkBuf bytes.Buffer
+	if _, err := vk.WriteTo(&vkBuf); err != nil {
+		return fmt.Errorf("failed to serialize verifying key: %v", err)
+	}
+	if err := os.WriteFile(vkPath(cacheDir, id), vkBuf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write cached verifying key: %v", err)
+	}
+
+	srsDumper, ok := srs.(dumper)
+	if !ok {
+		return fmt.Errorf("kzg SRS does not support the dump format")
+	}
+	if err := writeDump(srsDumpPath(cacheDir, id), srsDumper); err != nil {
+		return fmt.Errorf("failed to write cached SRS: %v", err)
+	}
+
+	srsLagrangeDumper, ok := srsLagrange.(dumper)
+	if !ok {
+		return fmt.Errorf("kzg SRS does not support the dump format")
+	}
+	if err := writeDump(srsLagrangeDumpPath(cacheDir, id), srsLagrangeDumper); err != nil {
+		return fmt.Errorf("failed to write cached SRS Lagrange: %v", err)
+	}
+
+	return writeDumpMeta(cacheDir, id, srsSource)
+}
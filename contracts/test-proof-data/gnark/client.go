@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+)
+
+// dialTimeout bounds how long the one-shot CLI waits to find out whether a
+// daemon is listening before falling back to the in-process path.
+const dialTimeout = 200 * time.Millisecond
+
+// proveViaDaemon relays a prove request to a running daemon on socketPath.
+// handled is false (with a nil error) when nothing is listening, in which
+// case the caller should fall back to its own in-process Setup+Prove.
+func proveViaDaemon(socketPath string, input ProverInput, jsonWitness bool) (output *ProofOutput, handled bool, err error) {
+	if jsonWitness {
+		// The debug JSON-witness path is local-only; don't round-trip it
+		// through the daemon protocol, which only speaks binary witnesses.
+		return nil, false, nil
+	}
+
+	conn, dialErr := net.DialTimeout("unix", socketPath, dialTimeout)
+	if dialErr != nil {
+		return nil, false, nil
+	}
+	defer conn.Close()
+
+	req := daemonRequest{
+		Op:            "prove",
+		CircuitID:     circuitID(input.R1CS, input.Curve, input.SchemeConfig),
+		R1CS:          input.R1CS,
+		Curve:         input.Curve,
+		SchemeConfig:  input.SchemeConfig,
+		SRS:           input.SRS,
+		SRSLagrange:   input.SRSLagrange,
+		WitnessBinary: input.WitnessBinary,
+	}
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, false, fmt.Errorf("failed to send request to daemon: %v", err)
+	}
+
+	var resp daemonResponse
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, false, fmt.Errorf("failed to read daemon response: %v", err)
+	}
+	if !resp.OK {
+		return nil, true, fmt.Errorf("daemon returned error: %s", resp.Error)
+	}
+
+	return &ProofOutput{
+		Proof:           resp.Proof,
+		PublicInputs:    resp.PublicInputs,
+		VerificationKey: resp.VerificationKey,
+	}, true, nil
+}
@@ -0,0 +1,416 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/test/unsafekzg"
+)
+
+// setupInput is the params file for the `setup` subcommand.
+type setupInput struct {
+	R1CS         []byte `json:"r1cs"`
+	Curve        string `json:"curve"`
+	SchemeConfig string `json:"scheme_config"`
+	// Optional SRS data for PLONK; when omitted, the SRS is derived from
+	// --ptau if given, else an unsafe development SRS is generated, same as
+	// the legacy combined command.
+	SRS         []byte `json:"srs,omitempty"`
+	SRSLagrange []byte `json:"srs_lagrange,omitempty"`
+}
+
+// setupOutput is written by the `setup` subcommand.
+type setupOutput struct {
+	ProvingKey       []byte `json:"proving_key"`
+	VerifyingKey     []byte `json:"verifying_key"`
+	SRS              []byte `json:"srs,omitempty"`
+	SRSLagrange      []byte `json:"srs_lagrange,omitempty"`
+	SolidityVerifier []byte `json:"solidity_verifier,omitempty"`
+}
+
+func runSetupCommand(args []string) error {
+	fs := flag.NewFlagSet("setup", flag.ExitOnError)
+	paramsPath := fs.String("params", "", "Path to setup params JSON file (r1cs, curve, scheme_config, optional srs/srs_lagrange for PLONK)")
+	outputPath := fs.String("output", "", "Path to write the proving/verifying key JSON output")
+	pkCacheDir := fs.String("pk-cache-dir", "", "Directory to persist/reuse the generated keys, keyed by a hash of the R1CS + curve + scheme")
+	exportSolidity := fs.Bool("export-solidity", false, "Include a generated Solidity verifier contract for the verifying key in the output JSON")
+	hashToField := fs.String("hash-to-field", "keccak256", "Hash-to-field function used by the exported Solidity verifier: keccak256 or sha256")
+	ptauPath := fs.String("ptau", "", "PLONK only: derive the SRS/SRS-Lagrange from a Powers-of-Tau ceremony file instead of params' srs/srs_lagrange or the unsafe test SRS")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *paramsPath == "" || *outputPath == "" {
+		return fmt.Errorf("both --params and --output are required")
+	}
+
+	data, err := os.ReadFile(*paramsPath)
+	if err != nil {
+		return fmt.Errorf("failed to read params file: %v", err)
+	}
+	var input setupInput
+	if err := json.Unmarshal(data, &input); err != nil {
+		return fmt.Errorf("failed to parse params JSON: %v", err)
+	}
+
+	curveID, err := parseCurve(input.Curve)
+	if err != nil {
+		return err
+	}
+	id := circuitID(input.R1CS, input.Curve, input.SchemeConfig)
+
+	var pk, vk interface{}
+	var srsOut, srsLagrangeOut []byte
+
+	switch input.SchemeConfig {
+	case "groth16":
+		if err := validateSchemeCurve("groth16", input.Curve); err != nil {
+			return err
+		}
+		r1cs := groth16.NewCS(curveID)
+		if _, err := r1cs.ReadFrom(bytes.NewReader(input.R1CS)); err != nil {
+			return fmt.Errorf("failed to parse R1CS: %v", err)
+		}
+
+		gpk, gvk, ok, err := loadGroth16Cache(*pkCacheDir, id, curveID)
+		if err != nil {
+			return fmt.Errorf("pk-cache-dir error: %v", err)
+		}
+		if !ok {
+			gpk, gvk, err = groth16.Setup(r1cs)
+			if err != nil {
+				return fmt.Errorf("setup error: %v", err)
+			}
+			if err := saveGroth16Cache(*pkCacheDir, id, gpk, gvk); err != nil {
+				return fmt.Errorf("pk-cache-dir error: %v", err)
+			}
+		}
+		pk, vk = gpk, gvk
+
+	case "plonk":
+		if err := validateSchemeCurve("plonk", input.Curve); err != nil {
+			return err
+		}
+		r1cs := plonk.NewCS(curveID)
+		if _, err := r1cs.ReadFrom(bytes.NewReader(input.R1CS)); err != nil {
+			return fmt.Errorf("failed to parse R1CS: %v", err)
+		}
+
+		srsSource, err := srsProvenance(input.SRS, input.SRSLagrange, *ptauPath)
+		if err != nil {
+			return fmt.Errorf("ptau error: %v", err)
+		}
+
+		ppk, pvk, srs, srsLagrange, ok, err := loadPlonkCache(*pkCacheDir, id, curveID, srsSource)
+		if err != nil {
+			return fmt.Errorf("pk-cache-dir error: %v", err)
+		}
+		if !ok {
+			// Precedence: an explicit SRS already provided over the wire,
+			// then a ptau ceremony file, then (for local testing only) an
+			// unsafe SRS.
+			if len(input.SRS) > 0 && len(input.SRSLagrange) > 0 {
+				srs = kzg.NewSRS(curveID)
+				srsLagrange = kzg.NewSRS(curveID)
+				if _, err := srs.ReadFrom(bytes.NewReader(input.SRS)); err != nil {
+					return fmt.Errorf("failed to parse SRS: %v", err)
+				}
+				if _, err := srsLagrange.ReadFrom(bytes.NewReader(input.SRSLagrange)); err != nil {
+					return fmt.Errorf("failed to parse SRS Lagrange: %v", err)
+				}
+			} else if *ptauPath != "" {
+				srs, srsLagrange, err = loadSRSFromPtau(*ptauPath, curveID, ptauMinDomainSize(r1cs))
+				if err != nil {
+					return fmt.Errorf("ptau error: %v", err)
+				}
+			} else {
+				// For testing/development: generate unsafe SRS
+				// WARNING: This should not be used in production!
+				srs, srsLagrange, err = unsafekzg.NewSRS(r1cs)
+				if err != nil {
+					return fmt.Errorf("failed to create test SRS: %v", err)
+				}
+			}
+
+			ppk, pvk, err = plonk.Setup(r1cs, srs, srsLagrange)
+			if err != nil {
+				return fmt.Errorf("setup error: %v", err)
+			}
+			if err := savePlonkCache(*pkCacheDir, id, ppk, pvk, srs, srsLagrange, srsSource); err != nil {
+				return fmt.Errorf("pk-cache-dir error: %v", err)
+			}
+		}
+		pk, vk = ppk, pvk
+
+		var srsBuf, srsLagrangeBuf bytes.Buffer
+		if _, err := srs.WriteTo(&srsBuf); err != nil {
+			return fmt.Errorf("failed to serialize SRS: %v", err)
+		}
+		if _, err := srsLagrange.WriteTo(&srsLagrangeBuf); err != nil {
+			return fmt.Errorf("failed to serialize SRS Lagrange: %v", err)
+		}
+		srsOut, srsLagrangeOut = srsBuf.Bytes(), srsLagrangeBuf.Bytes()
+
+	default:
+		return fmt.Errorf("unsupported scheme: %s", input.SchemeConfig)
+	}
+
+	pkWriter, ok := pk.(io.WriterTo)
+	if !ok {
+		return fmt.Errorf("proving key does not implement WriterTo")
+	}
+	vkWriter, ok := vk.(io.WriterTo)
+	if !ok {
+		return fmt.Errorf("verifying key does not implement WriterTo")
+	}
+	var pkBuf, vkBuf bytes.Buffer
+	if _, err := pkWriter.WriteTo(&pkBuf); err != nil {
+		return fmt.Errorf("failed to serialize proving key: %v", err)
+	}
+	if _, err := vkWriter.WriteTo(&vkBuf); err != nil {
+		return fmt.Errorf("failed to serialize verifying key: %v", err)
+	}
+
+	output := setupOutput{
+		ProvingKey:   pkBuf.Bytes(),
+		VerifyingKey: vkBuf.Bytes(),
+		SRS:          srsOut,
+		SRSLagrange:  srsLagrangeOut,
+	}
+
+	if *exportSolidity {
+		solBytes, err := exportSolidityVerifier(vk, *hashToField)
+		if err != nil {
+			return fmt.Errorf("failed to export Solidity verifier: %v", err)
+		}
+		output.SolidityVerifier = solBytes
+	}
+
+	return writeJSONFile(*outputPath, output)
+}
+
+// proveInput is the params file for the `prove` subcommand: the R1CS and a
+// proving key produced by `setup`, plus the witness to prove.
+type proveInput struct {
+	R1CS          []byte `json:"r1cs"`
+	ProvingKey    []byte `json:"proving_key"`
+	WitnessBinary []byte `json:"witness_binary"`
+	Curve         string `json:"curve"`
+	SchemeConfig  string `json:"scheme_config"`
+}
+
+// proveOutput is written by the `prove` subcommand: just the proof and the
+// public witness, no verifying key (that was already produced by `setup`).
+type proveOutput struct {
+	Proof               []byte   `json:"proof"`
+	PublicInputs        []byte   `json:"public_inputs"`
+	PublicInputsUint256 []string `json:"public_inputs_uint256,omitempty"`
+}
+
+func runProveCommand(args []string) error {
+	fs := flag.NewFlagSet("prove", flag.ExitOnError)
+	paramsPath := fs.String("params", "", "Path to prove params JSON file (r1cs, proving_key, witness_binary, curve, scheme_config)")
+	outputPath := fs.String("output", "", "Path to write the proof JSON output")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *paramsPath == "" || *outputPath == "" {
+		return fmt.Errorf("both --params and --output are required")
+	}
+
+	data, err := os.ReadFile(*paramsPath)
+	if err != nil {
+		return fmt.Errorf("failed to read params file: %v", err)
+	}
+	var input proveInput
+	if err := json.Unmarshal(data, &input); err != nil {
+		return fmt.Errorf("failed to parse params JSON: %v", err)
+	}
+
+	curveID, err := parseCurve(input.Curve)
+	if err != nil {
+		return err
+	}
+
+	w, err := witness.New(curveID.ScalarField())
+	if err != nil {
+		return fmt.Errorf("failed to create witness: %v", err)
+	}
+	if err := w.UnmarshalBinary(input.WitnessBinary); err != nil {
+		return fmt.Errorf("failed to unmarshal binary witness: %v", err)
+	}
+
+	var proofWriter io.WriterTo
+	switch input.SchemeConfig {
+	case "groth16":
+		if err := validateSchemeCurve("groth16", input.Curve); err != nil {
+			return err
+		}
+		r1cs := groth16.NewCS(curveID)
+		if _, err := r1cs.ReadFrom(bytes.NewReader(input.R1CS)); err != nil {
+			return fmt.Errorf("failed to parse R1CS: %v", err)
+		}
+		pk := groth16.NewProvingKey(curveID)
+		if _, err := pk.ReadFrom(bytes.NewReader(input.ProvingKey)); err != nil {
+			return fmt.Errorf("failed to parse proving key: %v", err)
+		}
+		proof, err := groth16.Prove(r1cs, pk, w)
+		if err != nil {
+			return fmt.Errorf("proving error: %v", err)
+		}
+		proofWriter = proof
+
+	case "plonk":
+		if err := validateSchemeCurve("plonk", input.Curve); err != nil {
+			return err
+		}
+		r1cs := plonk.NewCS(curveID)
+		if _, err := r1cs.ReadFrom(bytes.NewReader(input.R1CS)); err != nil {
+			return fmt.Errorf("failed to parse R1CS: %v", err)
+		}
+		pk := plonk.NewProvingKey(curveID)
+		if _, err := pk.ReadFrom(bytes.NewReader(input.ProvingKey)); err != nil {
+			return fmt.Errorf("failed to parse proving key: %v", err)
+		}
+		proof, err := plonk.Prove(r1cs, pk, w)
+		if err != nil {
+			return fmt.Errorf("proving error: %v", err)
+		}
+		proofWriter = proof
+
+	default:
+		return fmt.Errorf("unsupported scheme: %s", input.SchemeConfig)
+	}
+
+	var proofBuf bytes.Buffer
+	if _, err := proofWriter.WriteTo(&proofBuf); err != nil {
+		return fmt.Errorf("failed to serialize proof: %v", err)
+	}
+
+	publicWitness, err := w.Public()
+	if err != nil {
+		return fmt.Errorf("failed to extract public witness: %v", err)
+	}
+	var publicBuf bytes.Buffer
+	if _, err := publicWitness.WriteTo(&publicBuf); err != nil {
+		return fmt.Errorf("failed to serialize public witness: %v", err)
+	}
+	var publicInputsUint256 []string
+	if fitsUint256(curveID) {
+		publicInputsUint256, err = publicWitnessUint256(curveID, publicBuf.Bytes())
+		if err != nil {
+			return fmt.Errorf("failed to encode public inputs as uint256: %v", err)
+		}
+	}
+
+	return writeJSONFile(*outputPath, proveOutput{
+		Proof:               proofBuf.Bytes(),
+		PublicInputs:        publicBuf.Bytes(),
+		PublicInputsUint256: publicInputsUint256,
+	})
+}
+
+// verifyInput is the params file for the `verify` subcommand.
+type verifyInput struct {
+	VerifyingKey []byte `json:"verifying_key"`
+	Proof        []byte `json:"proof"`
+	PublicInputs []byte `json:"public_inputs"`
+	Curve        string `json:"curve"`
+	SchemeConfig string `json:"scheme_config"`
+}
+
+// runVerifyCommand exits non-zero (via log.Fatalf) on a malformed input or a
+// failed verification, and returns nil (exit 0) only once the proof checks
+// out -- usable from CI without spawning a prover.
+func runVerifyCommand(args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	paramsPath := fs.String("params", "", "Path to verify params JSON file (verifying_key, proof, public_inputs, curve, scheme_config)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *paramsPath == "" {
+		return fmt.Errorf("--params is required")
+	}
+
+	data, err := os.ReadFile(*paramsPath)
+	if err != nil {
+		return fmt.Errorf("failed to read params file: %v", err)
+	}
+	var input verifyInput
+	if err := json.Unmarshal(data, &input); err != nil {
+		return fmt.Errorf("failed to parse params JSON: %v", err)
+	}
+
+	curveID, err := parseCurve(input.Curve)
+	if err != nil {
+		return err
+	}
+
+	publicWitness, err := witness.New(curveID.ScalarField())
+	if err != nil {
+		return fmt.Errorf("failed to create witness: %v", err)
+	}
+	if err := publicWitness.UnmarshalBinary(input.PublicInputs); err != nil {
+		return fmt.Errorf("failed to unmarshal public witness: %v", err)
+	}
+
+	switch input.SchemeConfig {
+	case "groth16":
+		if err := validateSchemeCurve("groth16", input.Curve); err != nil {
+			return err
+		}
+		vk := groth16.NewVerifyingKey(curveID)
+		if _, err := vk.ReadFrom(bytes.NewReader(input.VerifyingKey)); err != nil {
+			return fmt.Errorf("failed to parse verifying key: %v", err)
+		}
+		proof := groth16.NewProof(curveID)
+		if _, err := proof.ReadFrom(bytes.NewReader(input.Proof)); err != nil {
+			return fmt.Errorf("failed to parse proof: %v", err)
+		}
+		if err := groth16.Verify(proof, vk, publicWitness); err != nil {
+			return fmt.Errorf("verification failed: %v", err)
+		}
+
+	case "plonk":
+		if err := validateSchemeCurve("plonk", input.Curve); err != nil {
+			return err
+		}
+		vk := plonk.NewVerifyingKey(curveID)
+		if _, err := vk.ReadFrom(bytes.NewReader(input.VerifyingKey)); err != nil {
+			return fmt.Errorf("failed to parse verifying key: %v", err)
+		}
+		proof := plonk.NewProof(curveID)
+		if _, err := proof.ReadFrom(bytes.NewReader(input.Proof)); err != nil {
+			return fmt.Errorf("failed to parse proof: %v", err)
+		}
+		if err := plonk.Verify(proof, vk, publicWitness); err != nil {
+			return fmt.Errorf("verification failed: %v", err)
+		}
+
+	default:
+		return fmt.Errorf("unsupported scheme: %s", input.SchemeConfig)
+	}
+
+	log.Println("verification OK")
+	return nil
+}
+
+func writeJSONFile(path string, v interface{}) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %v", err)
+	}
+	defer f.Close()
+	if err := json.NewEncoder(f).Encode(v); err != nil {
+		return fmt.Errorf("failed to write output: %v", err)
+	}
+	return nil
+}
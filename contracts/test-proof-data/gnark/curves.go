@@ -0,0 +1,37 @@
+package main
+
+import "fmt"
+
+// supportedSchemeCurves is the (scheme, curve) dispatch table: gnark's
+// groth16 and plonk packages are already generic over ecc.ID, so the table
+// only needs to gate which pairs this wrapper advertises as supported,
+// rather than branch to different backend code per pair.
+var supportedSchemeCurves = map[string]map[string]bool{
+	"groth16": {
+		"bn254":     true,
+		"bls12-381": true,
+		"bls12-377": true,
+		"bw6-761":   true,
+		"bls24-315": true,
+	},
+	"plonk": {
+		"bn254":     true,
+		"bls12-381": true,
+		"bls12-377": true,
+		"bw6-761":   true,
+		"bls24-315": true,
+	},
+}
+
+// validateSchemeCurve reports whether scheme supports curve, by name as
+// received over the wire (see parseCurve).
+func validateSchemeCurve(scheme, curve string) error {
+	curves, ok := supportedSchemeCurves[scheme]
+	if !ok {
+		return fmt.Errorf("unsupported scheme: %s", scheme)
+	}
+	if !curves[curve] {
+		return fmt.Errorf("unsupported (scheme, curve) pair: (%s, %s)", scheme, curve)
+	}
+	return nil
+}
@@ -0,0 +1,403 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/test/unsafekzg"
+)
+
+// defaultSocketPath is used by both --serve and the one-shot client's
+// dial-if-running check so the two agree without extra configuration.
+const defaultSocketPath = "/tmp/gnark-prover.sock"
+
+// daemonRequest is one request in the daemon's framed (one JSON value per
+// connection) protocol. Op selects which fields are required.
+type daemonRequest struct {
+	Op            string `json:"op"` // "setup", "prove", "verify", or "unload"
+	CircuitID     string `json:"circuit_id,omitempty"`
+	R1CS          []byte `json:"r1cs,omitempty"`
+	Curve         string `json:"curve,omitempty"`
+	SchemeConfig  string `json:"scheme_config,omitempty"`
+	SRS           []byte `json:"srs,omitempty"`
+	SRSLagrange   []byte `json:"srs_lagrange,omitempty"`
+	WitnessBinary []byte `json:"witness_binary,omitempty"`
+	Proof         []byte `json:"proof,omitempty"`
+	PublicWitness []byte `json:"public_witness,omitempty"`
+}
+
+// daemonResponse is the daemon's reply to a single daemonRequest.
+type daemonResponse struct {
+	OK              bool   `json:"ok"`
+	Error           string `json:"error,omitempty"`
+	Proof           []byte `json:"proof,omitempty"`
+	PublicInputs    []byte `json:"public_inputs,omitempty"`
+	VerificationKey []byte `json:"verification_key,omitempty"`
+}
+
+// cachedCircuit holds everything Prove/Verify need for one circuit ID so
+// Setup only runs once per circuit for the lifetime of the daemon.
+type cachedCircuit struct {
+	scheme  string
+	curveID ecc.ID
+
+	r1csGroth16 constraint.ConstraintSystem
+	pkGroth16   groth16.ProvingKey
+	vkGroth16   groth16.VerifyingKey
+
+	r1csPlonk constraint.ConstraintSystem
+	pkPlonk   plonk.ProvingKey
+	vkPlonk   plonk.VerifyingKey
+}
+
+// daemonServer is the in-memory cache backing --serve.
+type daemonServer struct {
+	mu       sync.Mutex
+	circuits map[string]*cachedCircuit
+}
+
+// circuitID derives a stable cache key from the R1CS bytes plus curve and
+// scheme, so repeated Setup requests for the same circuit collapse to one.
+func circuitID(r1cs []byte, curve, scheme string) string {
+	h := sha256.New()
+	h.Write(r1cs)
+	h.Write([]byte{0})
+	h.Write([]byte(curve))
+	h.Write([]byte{0})
+	h.Write([]byte(scheme))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// preloadManifestEntry describes one circuit to warm the cache with at
+// daemon startup, read from the --preload manifest file.
+type preloadManifestEntry struct {
+	CircuitID       string `json:"circuit_id,omitempty"`
+	R1CSPath        string `json:"r1cs_path"`
+	Curve           string `json:"curve"`
+	SchemeConfig    string `json:"scheme_config"`
+	SRSPath         string `json:"srs_path,omitempty"`
+	SRSLagrangePath string `json:"srs_lagrange_path,omitempty"`
+}
+
+// runDaemon listens on socketPath and serves setup/prove/verify/unload
+// requests until the process is killed, optionally warming the cache from
+// preloadPath first.
+func runDaemon(socketPath, preloadPath string) error {
+	d := &daemonServer{circuits: make(map[string]*cachedCircuit)}
+
+	if preloadPath != "" {
+		if err := d.preload(preloadPath); err != nil {
+			return fmt.Errorf("preload error: %v", err)
+		}
+	}
+
+	if err := os.RemoveAll(socketPath); err != nil {
+		return fmt.Errorf("failed to clear stale socket: %v", err)
+	}
+
+	ln, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %v", socketPath, err)
+	}
+	defer ln.Close()
+
+	log.Printf("gnark prover daemon listening on %s", socketPath)
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return fmt.Errorf("accept error: %v", err)
+		}
+		go d.handleConn(conn)
+	}
+}
+
+func (d *daemonServer) preload(manifestPath string) error {
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return fmt.Errorf("failed to read manifest: %v", err)
+	}
+
+	var entries []preloadManifestEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("failed to parse manifest: %v", err)
+	}
+
+	for _, e := range entries {
+		r1csBytes, err := os.ReadFile(e.R1CSPath)
+		if err != nil {
+			return fmt.Errorf("failed to read r1cs for %s: %v", e.R1CSPath, err)
+		}
+
+		var srs, srsLagrange []byte
+		if e.SRSPath != "" {
+			if srs, err = os.ReadFile(e.SRSPath); err != nil {
+				return fmt.Errorf("failed to read srs for %s: %v", e.R1CSPath, err)
+			}
+		}
+		if e.SRSLagrangePath != "" {
+			if srsLagrange, err = os.ReadFile(e.SRSLagrangePath); err != nil {
+				return fmt.Errorf("failed to read srs_lagrange for %s: %v", e.R1CSPath, err)
+			}
+		}
+
+		id := e.CircuitID
+		if id == "" {
+			id = circuitID(r1csBytes, e.Curve, e.SchemeConfig)
+		}
+
+		resp := d.setup(daemonRequest{
+			CircuitID:    id,
+			R1CS:         r1csBytes,
+			Curve:        e.Curve,
+			SchemeConfig: e.SchemeConfig,
+			SRS:          srs,
+			SRSLagrange:  srsLagrange,
+		})
+		if !resp.OK {
+			return fmt.Errorf("preload setup failed for %s: %s", e.R1CSPath, resp.Error)
+		}
+		log.Printf("preloaded circuit %s (%s/%s)", id, e.SchemeConfig, e.Curve)
+	}
+
+	return nil
+}
+
+func (d *daemonServer) handleConn(conn net.Conn) {
+	defer conn.Close()
+
+	var req daemonRequest
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		json.NewEncoder(conn).Encode(daemonResponse{Error: fmt.Sprintf("failed to decode request: %v", err)})
+		return
+	}
+
+	var resp daemonResponse
+	switch req.Op {
+	case "setup":
+		resp = d.setup(req)
+	case "prove":
+		resp = d.prove(req)
+	case "verify":
+		resp = d.verify(req)
+	case "unload":
+		resp = d.unload(req)
+	default:
+		resp = daemonResponse{Error: fmt.Sprintf("unsupported op: %s", req.Op)}
+	}
+
+	if err := json.NewEncoder(conn).Encode(resp); err != nil {
+		log.Printf("failed to encode response: %v", err)
+	}
+}
+
+func (d *daemonServer) setup(req daemonRequest) daemonResponse {
+	curveID, err := parseCurve(req.Curve)
+	if err != nil {
+		return daemonResponse{Error: err.Error()}
+	}
+
+	id := req.CircuitID
+	if id == "" {
+		id = circuitID(req.R1CS, req.Curve, req.SchemeConfig)
+	}
+
+	d.mu.Lock()
+	if _, ok := d.circuits[id]; ok {
+		d.mu.Unlock()
+		return daemonResponse{OK: true}
+	}
+	d.mu.Unlock()
+
+	entry := &cachedCircuit{scheme: req.SchemeConfig, curveID: curveID}
+
+	switch req.SchemeConfig {
+	case "groth16":
+		if err := validateSchemeCurve("groth16", req.Curve); err != nil {
+			return daemonResponse{Error: err.Error()}
+		}
+		r1cs := groth16.NewCS(curveID)
+		if _, err := r1cs.ReadFrom(bytes.NewReader(req.R1CS)); err != nil {
+			return daemonResponse{Error: fmt.Sprintf("failed to parse R1CS: %v", err)}
+		}
+		pk, vk, err := groth16.Setup(r1cs)
+		if err != nil {
+			return daemonResponse{Error: fmt.Sprintf("setup error: %v", err)}
+		}
+		entry.r1csGroth16, entry.pkGroth16, entry.vkGroth16 = r1cs, pk, vk
+
+	case "plonk":
+		if err := validateSchemeCurve("plonk", req.Curve); err != nil {
+			return daemonResponse{Error: err.Error()}
+		}
+		r1cs := plonk.NewCS(curveID)
+		if _, err := r1cs.ReadFrom(bytes.NewReader(req.R1CS)); err != nil {
+			return daemonResponse{Error: fmt.Sprintf("failed to parse R1CS: %v", err)}
+		}
+
+		srs := kzg.NewSRS(curveID)
+		srsLagrange := kzg.NewSRS(curveID)
+		if len(req.SRS) > 0 && len(req.SRSLagrange) > 0 {
+			if _, err := srs.ReadFrom(bytes.NewReader(req.SRS)); err != nil {
+				return daemonResponse{Error: fmt.Sprintf("failed to parse SRS: %v", err)}
+			}
+			if _, err := srsLagrange.ReadFrom(bytes.NewReader(req.SRSLagrange)); err != nil {
+				return daemonResponse{Error: fmt.Sprintf("failed to parse SRS Lagrange: %v", err)}
+			}
+		} else {
+			// WARNING: unsafe, testing/development only, see handlePlonk.
+			var err error
+			srs, srsLagrange, err = unsafekzg.NewSRS(r1cs)
+			if err != nil {
+				return daemonResponse{Error: fmt.Sprintf("failed to create test SRS: %v", err)}
+			}
+		}
+
+		pk, vk, err := plonk.Setup(r1cs, srs, srsLagrange)
+		if err != nil {
+			return daemonResponse{Error: fmt.Sprintf("setup error: %v", err)}
+		}
+		entry.r1csPlonk, entry.pkPlonk, entry.vkPlonk = r1cs, pk, vk
+
+	default:
+		return daemonResponse{Error: fmt.Sprintf("unsupported scheme: %s", req.SchemeConfig)}
+	}
+
+	d.mu.Lock()
+	d.circuits[id] = entry
+	d.mu.Unlock()
+
+	return daemonResponse{OK: true}
+}
+
+func (d *daemonServer) prove(req daemonRequest) daemonResponse {
+	// Setup is cheap to skip if already cached, and lets callers fold
+	// "setup if needed, then prove" into one round trip.
+	if setupResp := d.setup(req); !setupResp.OK {
+		return setupResp
+	}
+
+	id := req.CircuitID
+	if id == "" {
+		id = circuitID(req.R1CS, req.Curve, req.SchemeConfig)
+	}
+
+	d.mu.Lock()
+	entry, ok := d.circuits[id]
+	d.mu.Unlock()
+	if !ok {
+		return daemonResponse{Error: fmt.Sprintf("unknown circuit_id: %s", id)}
+	}
+
+	w, err := witness.New(entry.curveID.ScalarField())
+	if err != nil {
+		return daemonResponse{Error: fmt.Sprintf("failed to create witness: %v", err)}
+	}
+	if err := w.UnmarshalBinary(req.WitnessBinary); err != nil {
+		return daemonResponse{Error: fmt.Sprintf("failed to unmarshal binary witness: %v", err)}
+	}
+
+	publicWitness, err := w.Public()
+	if err != nil {
+		return daemonResponse{Error: fmt.Sprintf("failed to extract public witness: %v", err)}
+	}
+	publicBuf := new(bytes.Buffer)
+	if _, err := publicWitness.WriteTo(publicBuf); err != nil {
+		return daemonResponse{Error: fmt.Sprintf("failed to serialize public witness: %v", err)}
+	}
+
+	var proofBuf, vkBuf bytes.Buffer
+	switch entry.scheme {
+	case "groth16":
+		proof, err := groth16.Prove(entry.r1csGroth16, entry.pkGroth16, w)
+		if err != nil {
+			return daemonResponse{Error: fmt.Sprintf("proving error: %v", err)}
+		}
+		if _, err := proof.WriteTo(&proofBuf); err != nil {
+			return daemonResponse{Error: fmt.Sprintf("failed to serialize proof: %v", err)}
+		}
+		if _, err := entry.vkGroth16.WriteTo(&vkBuf); err != nil {
+			return daemonResponse{Error: fmt.Sprintf("failed to serialize verification key: %v", err)}
+		}
+	case "plonk":
+		proof, err := plonk.Prove(entry.r1csPlonk, entry.pkPlonk, w)
+		if err != nil {
+			return daemonResponse{Error: fmt.Sprintf("proving error: %v", err)}
+		}
+		if _, err := proof.WriteTo(&proofBuf); err != nil {
+			return daemonResponse{Error: fmt.Sprintf("failed to serialize proof: %v", err)}
+		}
+		if _, err := entry.vkPlonk.WriteTo(&vkBuf); err != nil {
+			return daemonResponse{Error: fmt.Sprintf("failed to serialize verification key: %v", err)}
+		}
+	default:
+		return daemonResponse{Error: fmt.Sprintf("unsupported scheme: %s", entry.scheme)}
+	}
+
+	return daemonResponse{
+		OK:              true,
+		Proof:           proofBuf.Bytes(),
+		PublicInputs:    publicBuf.Bytes(),
+		VerificationKey: vkBuf.Bytes(),
+	}
+}
+
+func (d *daemonServer) verify(req daemonRequest) daemonResponse {
+	d.mu.Lock()
+	entry, ok := d.circuits[req.CircuitID]
+	d.mu.Unlock()
+	if !ok {
+		return daemonResponse{Error: fmt.Sprintf("unknown circuit_id: %s", req.CircuitID)}
+	}
+
+	publicWitness, err := witness.New(entry.curveID.ScalarField())
+	if err != nil {
+		return daemonResponse{Error: fmt.Sprintf("failed to create witness: %v", err)}
+	}
+	if _, err := publicWitness.ReadFrom(bytes.NewReader(req.PublicWitness)); err != nil {
+		return daemonResponse{Error: fmt.Sprintf("failed to parse public witness: %v", err)}
+	}
+
+	switch entry.scheme {
+	case "groth16":
+		proof := groth16.NewProof(entry.curveID)
+		if _, err := proof.ReadFrom(bytes.NewReader(req.Proof)); err != nil {
+			return daemonResponse{Error: fmt.Sprintf("failed to parse proof: %v", err)}
+		}
+		if err := groth16.Verify(proof, entry.vkGroth16, publicWitness); err != nil {
+			return daemonResponse{Error: fmt.Sprintf("verification failed: %v", err)}
+		}
+	case "plonk":
+		proof := plonk.NewProof(entry.curveID)
+		if _, err := proof.ReadFrom(bytes.NewReader(req.Proof)); err != nil {
+			return daemonResponse{Error: fmt.Sprintf("failed to parse proof: %v", err)}
+		}
+		if err := plonk.Verify(proof, entry.vkPlonk, publicWitness); err != nil {
+			return daemonResponse{Error: fmt.Sprintf("verification failed: %v", err)}
+		}
+	default:
+		return daemonResponse{Error: fmt.Sprintf("unsupported scheme: %s", entry.scheme)}
+	}
+
+	return daemonResponse{OK: true}
+}
+
+func (d *daemonServer) unload(req daemonRequest) daemonResponse {
+	d.mu.Lock()
+	delete(d.circuits, req.CircuitID)
+	d.mu.Unlock()
+	return daemonResponse{OK: true}
+}
@@ -20,9 +20,18 @@ import (
 
 // ProverInput represents the JSON structure passed from Rust
 type ProverInput struct {
-	R1CS          []byte          `json:"r1cs"`
-	PublicInputs  json.RawMessage `json:"public_inputs"`
-	PrivateInputs json.RawMessage `json:"private_inputs"`
+	R1CS []byte `json:"r1cs"`
+	// WitnessBinary is gnark's canonical binary witness encoding
+	// ([nbPublic uint32 | nbSecret uint32 | n uint32 | n * fr.Element],
+	// all big-endian), with values in declaration order. This is the
+	// production path: unlike JSON object iteration, it has no ordering
+	// ambiguity.
+	WitnessBinary []byte `json:"witness_binary,omitempty"`
+	// PublicInputs and PrivateInputs are a JSON debugging fallback only
+	// (see --json-witness); do not rely on them in production, map
+	// iteration order is not guaranteed to match wire declaration order.
+	PublicInputs  json.RawMessage `json:"public_inputs,omitempty"`
+	PrivateInputs json.RawMessage `json:"private_inputs,omitempty"`
 	SchemeConfig  string          `json:"scheme_config"`
 	Curve         string          `json:"curve"`
 	// Optional SRS data for PLONK
@@ -35,14 +44,56 @@ type ProofOutput struct {
 	Proof           []byte `json:"proof"`
 	PublicInputs    []byte `json:"public_inputs"`
 	VerificationKey []byte `json:"verification_key"`
+	// PublicInputsUint256 mirrors PublicInputs as 0x-prefixed uint256 hex
+	// strings, in wire order, ready to splice into a generated verifier
+	// contract's verifyProof(uint256[] calldata) argument.
+	PublicInputsUint256 []string `json:"public_inputs_uint256,omitempty"`
+	// SolidityVerifier holds the source generated by vk.ExportSolidity when
+	// --export-solidity is set; omitted otherwise.
+	SolidityVerifier []byte `json:"solidity_verifier,omitempty"`
+}
+
+// subcommands dispatches to the setup/prove/verify CLI (matching
+// snarkjs/circom/risc0's shape, and usable from CI without spawning a full
+// prove just to check a proof). Invoking the binary without one of these as
+// the first argument keeps the legacy combined setup+prove behavior below,
+// so existing callers built against requests chunk0-1..chunk0-5 keep working.
+var subcommands = map[string]func([]string) error{
+	"setup":  runSetupCommand,
+	"prove":  runProveCommand,
+	"verify": runVerifyCommand,
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			if err := run(os.Args[2:]); err != nil {
+				log.Fatalf("%s error: %v", os.Args[1], err)
+			}
+			return
+		}
+	}
+
 	// Parse command line flags
 	paramsPath := flag.String("params", "", "Path to params JSON file")
 	outputPath := flag.String("output", "", "Path for proof output")
+	jsonWitness := flag.Bool("json-witness", false, "Debug only: fill the witness from public_inputs/private_inputs JSON maps instead of witness_binary")
+	serve := flag.Bool("serve", false, "Run as a long-lived daemon that caches (r1cs, pk, vk) across requests instead of exiting after one proof")
+	socketPath := flag.String("socket", defaultSocketPath, "Unix domain socket path for daemon mode and for the client's dial-if-running check")
+	preloadPath := flag.String("preload", "", "Manifest of circuits to warm the cache with at daemon startup (only used with --serve)")
+	pkCacheDir := flag.String("pk-cache-dir", "", "Directory to persist ProvingKey/VerifyingKey (and, for PLONK, SRS/SRS-Lagrange) across invocations, keyed by a hash of the R1CS + curve + scheme")
+	exportSolidity := flag.Bool("export-solidity", false, "Include a generated Solidity verifier contract for the verifying key in the output JSON")
+	hashToField := flag.String("hash-to-field", "keccak256", "Hash-to-field function used by the exported Solidity verifier: keccak256 or sha256")
+	ptauPath := flag.String("ptau", "", "PLONK only: derive the SRS/SRS-Lagrange from a Powers-of-Tau ceremony file instead of params' srs/srs_lagrange or the unsafe test SRS")
 	flag.Parse()
 
+	if *serve {
+		if err := runDaemon(*socketPath, *preloadPath); err != nil {
+			log.Fatalf("Daemon error: %v", err)
+		}
+		return
+	}
+
 	if *paramsPath == "" || *outputPath == "" {
 		log.Fatal("Both --params and --output flags are required")
 	}
@@ -58,25 +109,39 @@ func main() {
 		log.Fatalf("Failed to parse params JSON: %v", err)
 	}
 
+	// Thin client: if a daemon is listening on socketPath, let it do the
+	// (cached) Setup and Prove and just relay its output. Falls through to
+	// the in-process path below if nothing is listening, or if
+	// --export-solidity or --ptau was requested: the daemon protocol carries
+	// neither, and the daemon's setup()/prove() know nothing about --ptau, so
+	// routing those requests to it would silently fall back to the unsafe
+	// test SRS. --pk-cache-dir is not passed to the daemon, which keeps its
+	// keys in memory only.
+	if !*exportSolidity && *ptauPath == "" {
+		if output, handled, err := proveViaDaemon(*socketPath, input, *jsonWitness); err != nil {
+			log.Fatalf("Daemon request failed: %v", err)
+		} else if handled {
+			if err := writeProofOutput(output, *outputPath); err != nil {
+				log.Fatalf("Failed to write output: %v", err)
+			}
+			return
+		}
+	}
+
 	// Get curve type
-	var curveID ecc.ID
-	switch input.Curve {
-	case "bn254":
-		curveID = ecc.BN254
-	case "bls12-381":
-		curveID = ecc.BLS12_381
-	default:
-		log.Fatalf("Unsupported curve: %s", input.Curve)
+	curveID, err := parseCurve(input.Curve)
+	if err != nil {
+		log.Fatal(err)
 	}
 
 	// Handle different proving schemes
 	switch input.SchemeConfig {
 	case "groth16":
-		if err := handleGroth16(input, curveID, *outputPath); err != nil {
+		if err := handleGroth16(input, curveID, *outputPath, *jsonWitness, *pkCacheDir, *exportSolidity, *hashToField); err != nil {
 			log.Fatalf("Groth16 error: %v", err)
 		}
 	case "plonk":
-		if err := handlePlonk(input, curveID, *outputPath); err != nil {
+		if err := handlePlonk(input, curveID, *outputPath, *jsonWitness, *pkCacheDir, *exportSolidity, *hashToField, *ptauPath); err != nil {
 			log.Fatalf("PLONK error: %v", err)
 		}
 	default:
@@ -84,9 +149,37 @@ func main() {
 	}
 }
 
-func handleGroth16(input ProverInput, curveID ecc.ID, outputPath string) error {
-	if curveID != ecc.BN254 {
-		return fmt.Errorf("Groth16 only supports bn254 curve")
+// parseCurve maps the wire curve name to a gnark-crypto curve ID.
+func parseCurve(curve string) (ecc.ID, error) {
+	switch curve {
+	case "bn254":
+		return ecc.BN254, nil
+	case "bls12-381":
+		return ecc.BLS12_381, nil
+	case "bls12-377":
+		return ecc.BLS12_377, nil
+	case "bw6-761":
+		return ecc.BW6_761, nil
+	case "bls24-315":
+		return ecc.BLS24_315, nil
+	default:
+		return 0, fmt.Errorf("unsupported curve: %s", curve)
+	}
+}
+
+// handleGroth16 does not support commit-and-prove (Pedersen-committed
+// private wires): that requires the committed variables to be declared
+// in-circuit, via frontend.API.Commit at compile time, so the commitment
+// is folded into Groth16's own Fiat-Shamir transcript and checked as part
+// of groth16.Verify. This wrapper only ever sees an already-compiled R1CS
+// (see ProverInput.R1CS), so it has no hook to add that after the fact --
+// an out-of-band Pedersen commitment computed here from the witness alone
+// would not bind to the specific proof it accompanies. An R1CS compiled
+// elsewhere with its own in-circuit commitments already round-trips
+// through groth16.Setup/Prove/Verify with zero extra code in this file.
+func handleGroth16(input ProverInput, curveID ecc.ID, outputPath string, jsonWitness bool, pkCacheDir string, exportSolidity bool, hashToField string) error {
+	if err := validateSchemeCurve("groth16", input.Curve); err != nil {
+		return err
 	}
 
 	// Create new R1CS
@@ -97,14 +190,25 @@ func handleGroth16(input ProverInput, curveID ecc.ID, outputPath string) error {
 		return fmt.Errorf("failed to parse R1CS: %v", err)
 	}
 
-	// Setup
-	pk, vk, err := groth16.Setup(r1cs)
+	id := circuitID(input.R1CS, input.Curve, input.SchemeConfig)
+
+	pk, vk, ok, err := loadGroth16Cache(pkCacheDir, id, curveID)
 	if err != nil {
-		return fmt.Errorf("setup error: %v", err)
+		return fmt.Errorf("pk-cache-dir error: %v", err)
+	}
+	if !ok {
+		// Setup
+		pk, vk, err = groth16.Setup(r1cs)
+		if err != nil {
+			return fmt.Errorf("setup error: %v", err)
+		}
+		if err := saveGroth16Cache(pkCacheDir, id, pk, vk); err != nil {
+			return fmt.Errorf("pk-cache-dir error: %v", err)
+		}
 	}
 
 	// Create and fill witness
-	w, err := createWitness(input, curveID, r1cs)
+	w, err := createWitness(input, curveID, r1cs, jsonWitness)
 	if err != nil {
 		return fmt.Errorf("witness error: %v", err)
 	}
@@ -115,10 +219,14 @@ func handleGroth16(input ProverInput, curveID ecc.ID, outputPath string) error {
 		return fmt.Errorf("proving error: %v", err)
 	}
 
-	return writeOutput(proof, vk, input.PublicInputs, outputPath)
+	return writeOutput(proof, vk, curveID, w, outputPath, exportSolidity, hashToField)
 }
 
-func handlePlonk(input ProverInput, curveID ecc.ID, outputPath string) error {
+func handlePlonk(input ProverInput, curveID ecc.ID, outputPath string, jsonWitness bool, pkCacheDir string, exportSolidity bool, hashToField string, ptauPath string) error {
+	if err := validateSchemeCurve("plonk", input.Curve); err != nil {
+		return err
+	}
+
 	// Create new constraint system
 	r1cs := plonk.NewCS(curveID)
 
@@ -127,38 +235,55 @@ func handlePlonk(input ProverInput, curveID ecc.ID, outputPath string) error {
 		return fmt.Errorf("failed to parse R1CS: %v", err)
 	}
 
-	var srs, srsLagrange kzg.SRS
-	srs = kzg.NewSRS(curveID)
-	srsLagrange = kzg.NewSRS(curveID)
+	id := circuitID(input.R1CS, input.Curve, input.SchemeConfig)
 
-	// If SRS data is provided, use it
-	if len(input.SRS) > 0 && len(input.SRSLagrange) > 0 {
-		// Parse provided SRS data
-		if _, err := srs.ReadFrom(bytes.NewReader(input.SRS)); err != nil {
-			return fmt.Errorf("failed to parse SRS: %v", err)
-		}
-		if _, err := srsLagrange.ReadFrom(bytes.NewReader(input.SRSLagrange)); err != nil {
-			return fmt.Errorf("failed to parse SRS Lagrange: %v", err)
-		}
-	} else {
-		// For testing/development: generate unsafe SRS
-		// WARNING: This should not be used in production!
-		srsTemp, srsLagrangeTemp, err := unsafekzg.NewSRS(r1cs)
-		if err != nil {
-			return fmt.Errorf("failed to create test SRS: %v", err)
-		}
-		srs = srsTemp
-		srsLagrange = srsLagrangeTemp
+	srsSource, err := srsProvenance(input.SRS, input.SRSLagrange, ptauPath)
+	if err != nil {
+		return fmt.Errorf("ptau error: %v", err)
 	}
 
-	// Setup
-	pk, vk, err := plonk.Setup(r1cs, srs, srsLagrange)
+	pk, vk, srs, srsLagrange, ok, err := loadPlonkCache(pkCacheDir, id, curveID, srsSource)
 	if err != nil {
-		return fmt.Errorf("setup error: %v", err)
+		return fmt.Errorf("pk-cache-dir error: %v", err)
+	}
+	if !ok {
+		// Precedence: an explicit SRS already provided over the wire, then a
+		// ptau ceremony file, then (for local testing only) an unsafe SRS.
+		if len(input.SRS) > 0 && len(input.SRSLagrange) > 0 {
+			srs = kzg.NewSRS(curveID)
+			srsLagrange = kzg.NewSRS(curveID)
+			if _, err := srs.ReadFrom(bytes.NewReader(input.SRS)); err != nil {
+				return fmt.Errorf("failed to parse SRS: %v", err)
+			}
+			if _, err := srsLagrange.ReadFrom(bytes.NewReader(input.SRSLagrange)); err != nil {
+				return fmt.Errorf("failed to parse SRS Lagrange: %v", err)
+			}
+		} else if ptauPath != "" {
+			srs, srsLagrange, err = loadSRSFromPtau(ptauPath, curveID, ptauMinDomainSize(r1cs))
+			if err != nil {
+				return fmt.Errorf("ptau error: %v", err)
+			}
+		} else {
+			// For testing/development: generate unsafe SRS
+			// WARNING: This should not be used in production!
+			srs, srsLagrange, err = unsafekzg.NewSRS(r1cs)
+			if err != nil {
+				return fmt.Errorf("failed to create test SRS: %v", err)
+			}
+		}
+
+		// Setup
+		pk, vk, err = plonk.Setup(r1cs, srs, srsLagrange)
+		if err != nil {
+			return fmt.Errorf("setup error: %v", err)
+		}
+		if err := savePlonkCache(pkCacheDir, id, pk, vk, srs, srsLagrange, srsSource); err != nil {
+			return fmt.Errorf("pk-cache-dir error: %v", err)
+		}
 	}
 
 	// Create and fill witness
-	w, err := createWitness(input, curveID, r1cs)
+	w, err := createWitness(input, curveID, r1cs, jsonWitness)
 	if err != nil {
 		return fmt.Errorf("witness error: %v", err)
 	}
@@ -169,15 +294,32 @@ func handlePlonk(input ProverInput, curveID ecc.ID, outputPath string) error {
 		return fmt.Errorf("proving error: %v", err)
 	}
 
-	return writeOutput(proof, vk, input.PublicInputs, outputPath)
+	return writeOutput(proof, vk, curveID, w, outputPath, exportSolidity, hashToField)
 }
 
-func createWitness(input ProverInput, curveID ecc.ID, cs interface{}) (witness.Witness, error) {
+func createWitness(input ProverInput, curveID ecc.ID, cs interface{}, jsonWitness bool) (witness.Witness, error) {
 	w, err := witness.New(curveID.ScalarField())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create witness: %v", err)
 	}
 
+	if !jsonWitness {
+		// Canonical path: the binary encoding carries an explicit element
+		// count and is filled in declaration order by the caller, so there
+		// is no map-iteration ordering hazard.
+		if len(input.WitnessBinary) == 0 {
+			return nil, fmt.Errorf("witness_binary is required (pass --json-witness to use the debug JSON path instead)")
+		}
+		if err := w.UnmarshalBinary(input.WitnessBinary); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal binary witness: %v", err)
+		}
+		return w, nil
+	}
+
+	// Debug-only fallback: map iteration order in Go is unspecified, so this
+	// path silently misassigns wires as soon as a circuit has more than one
+	// public or secret variable. Kept for local debugging against circuits
+	// with a single variable per side; never used in production.
 	var publicInputs, privateInputs map[string]interface{}
 	if err := json.Unmarshal(input.PublicInputs, &publicInputs); err != nil {
 		return nil, fmt.Errorf("failed to parse public inputs: %v", err)
@@ -215,7 +357,7 @@ func createWitness(input ProverInput, curveID ecc.ID, cs interface{}) (witness.W
 	return w, nil
 }
 
-func writeOutput(proof, vk interface{}, publicInputs json.RawMessage, outputPath string) error {
+func writeOutput(proof, vk interface{}, curveID ecc.ID, w witness.Witness, outputPath string, exportSolidity bool, hashToField string) error {
 	var proofBuf, vkBuf bytes.Buffer
 
 	if p, ok := proof.(io.WriterTo); ok {
@@ -234,21 +376,44 @@ func writeOutput(proof, vk interface{}, publicInputs json.RawMessage, outputPath
 		return fmt.Errorf("verification key does not implement WriterTo")
 	}
 
-	output := ProofOutput{
-		Proof:           proofBuf.Bytes(),
-		PublicInputs:    publicInputs,
-		VerificationKey: vkBuf.Bytes(),
+	publicWitness, err := w.Public()
+	if err != nil {
+		return fmt.Errorf("failed to extract public witness: %v", err)
+	}
+	var publicBuf bytes.Buffer
+	if _, err := publicWitness.WriteTo(&publicBuf); err != nil {
+		return fmt.Errorf("failed to serialize public witness: %v", err)
+	}
+	var publicInputsUint256 []string
+	if fitsUint256(curveID) {
+		publicInputsUint256, err = publicWitnessUint256(curveID, publicBuf.Bytes())
+		if err != nil {
+			return fmt.Errorf("failed to encode public inputs as uint256: %v", err)
+		}
+	} else if exportSolidity {
+		return fmt.Errorf("cannot export a Solidity verifier for curve %s: its field is wider than 256 bits, so public inputs don't fit a uint256[] calldata argument", curveID.String())
 	}
 
-	outputFile, err := os.Create(outputPath)
-	if err != nil {
-		return fmt.Errorf("failed to create output file: %v", err)
+	output := &ProofOutput{
+		Proof:               proofBuf.Bytes(),
+		PublicInputs:        publicBuf.Bytes(),
+		VerificationKey:     vkBuf.Bytes(),
+		PublicInputsUint256: publicInputsUint256,
 	}
-	defer outputFile.Close()
 
-	if err := json.NewEncoder(outputFile).Encode(output); err != nil {
-		return fmt.Errorf("failed to write output: %v", err)
+	if exportSolidity {
+		solBuf, err := exportSolidityVerifier(vk, hashToField)
+		if err != nil {
+			return fmt.Errorf("failed to export Solidity verifier: %v", err)
+		}
+		output.SolidityVerifier = solBuf
 	}
 
-	return nil
+	return writeProofOutput(output, outputPath)
+}
+
+// writeProofOutput writes an already-assembled ProofOutput to outputPath,
+// shared by the in-process path and the thin client relaying a daemon reply.
+func writeProofOutput(output *ProofOutput, outputPath string) error {
+	return writeJSONFile(outputPath, output)
 }
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/big"
+	"math/bits"
+	"os"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
+	bn254kzg "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	"github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/constraint"
+)
+
+// ptau files (snarkjs / Perpetual Powers of Tau) are a versioned,
+// section-table container: a 4-byte magic, a uint32 version, a uint32
+// section count, then that many (sectionType uint32, sectionSize uint64,
+// payload) records, all little-endian. Field elements and curve point
+// coordinates inside sections are also little-endian, uncompressed.
+const (
+	ptauSectionHeader      = 1
+	ptauSectionTauG1       = 2
+	ptauSectionTauG2       = 3
+	ptauSectionAlphaTauG1  = 4
+	ptauSectionBetaTauG1   = 5
+	ptauSectionBetaG2      = 6
+	ptauSectionContributes = 7
+)
+
+var ptauMagic = [4]byte{'p', 't', 'a', 'u'}
+
+// loadSRSFromPtau reads a ptau file and returns a monomial-basis SRS plus
+// its Lagrange-basis SRS (derived by an inverse FFT over the domain sized
+// to minDomainSize), ready to feed into plonk.Setup. Only bn254 is
+// supported: that's the curve every public Powers-of-Tau ceremony targets.
+func loadSRSFromPtau(path string, curveID ecc.ID, minDomainSize uint64) (srs, srsLagrange kzg.SRS, err error) {
+	if curveID != ecc.BN254 {
+		return nil, nil, fmt.Errorf("--ptau is only supported on bn254")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open ptau file: %v", err)
+	}
+	defer f.Close()
+
+	sections, err := readPtauSections(f)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	n8, prime, power, err := parsePtauHeader(sections[ptauSectionHeader])
+	if err != nil {
+		return nil, nil, err
+	}
+	if want := fp.Modulus(); prime.Cmp(want) != 0 {
+		return nil, nil, fmt.Errorf("ptau prime does not match curve %s", curveID.String())
+	}
+
+	domainSize := uint64(1) << power
+	if domainSize < minDomainSize {
+		return nil, nil, fmt.Errorf("ptau file too small for this circuit: has 2^%d=%d, need at least %d", power, domainSize, minDomainSize)
+	}
+
+	// The tauG1 section carries 2^(power+1)-1 points (tau^0 .. tau^(2^(power+1)-2));
+	// a degree-domainSize KZG SRS only needs the first domainSize of them.
+	tauG1, err := parsePtauG1Points(sections[ptauSectionTauG1], n8, domainSize)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse tauG1 section: %v", err)
+	}
+
+	// The tauG2 section carries 2^power points, tau^0 * G2 .. tau^(2^power-1) * G2;
+	// element 1 is the tau * G2 point the KZG verifying key needs.
+	tauG2, err := parsePtauG2Points(sections[ptauSectionTauG2], n8, 2)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse tauG2 section: %v", err)
+	}
+
+	// alphaTauG1/betaTauG1/betaG2 are Groth16-ceremony leftovers; KZG/PLONK
+	// doesn't use them, so they're left unparsed.
+
+	_, _, g1Gen, g2Gen := bn254.Generators()
+
+	monomial := bn254kzg.SRS{
+		Pk: bn254kzg.ProvingKey{G1: tauG1},
+		Vk: bn254kzg.VerifyingKey{G1: g1Gen, G2: [2]bn254.G2Affine{g2Gen, tauG2[1]}},
+	}
+
+	lagrangeG1, err := tauG1ToLagrangeG1(tauG1[:minDomainSize], fft.NewDomain(minDomainSize))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to derive Lagrange-basis SRS: %v", err)
+	}
+	lagrange := bn254kzg.SRS{
+		Pk: bn254kzg.ProvingKey{G1: lagrangeG1},
+		Vk: monomial.Vk,
+	}
+
+	if srs, err = srsFromConcrete(curveID, &monomial); err != nil {
+		return nil, nil, err
+	}
+	if srsLagrange, err = srsFromConcrete(curveID, &lagrange); err != nil {
+		return nil, nil, err
+	}
+	return srs, srsLagrange, nil
+}
+
+// readPtauSections reads the magic/version/section-count preamble and
+// returns every section's raw payload keyed by section type.
+func readPtauSections(f io.Reader) (map[uint32][]byte, error) {
+	var magic [4]byte
+	if _, err := io.ReadFull(f, magic[:]); err != nil {
+		return nil, fmt.Errorf("failed to read ptau magic: %v", err)
+	}
+	if magic != ptauMagic {
+		return nil, fmt.Errorf("not a ptau file (bad magic)")
+	}
+
+	var version uint32
+	if err := binary.Read(f, binary.LittleEndian, &version); err != nil {
+		return nil, fmt.Errorf("failed to read ptau version: %v", err)
+	}
+
+	var numSections uint32
+	if err := binary.Read(f, binary.LittleEndian, &numSections); err != nil {
+		return nil, fmt.Errorf("failed to read ptau section count: %v", err)
+	}
+
+	sections := make(map[uint32][]byte, numSections)
+	for i := uint32(0); i < numSections; i++ {
+		var sectionType uint32
+		if err := binary.Read(f, binary.LittleEndian, &sectionType); err != nil {
+			return nil, fmt.Errorf("failed to read section %d type: %v", i, err)
+		}
+		var sectionSize uint64
+		if err := binary.Read(f, binary.LittleEndian, &sectionSize); err != nil {
+			return nil, fmt.Errorf("failed to read section %d size: %v", i, err)
+		}
+		payload := make([]byte, sectionSize)
+		if _, err := io.ReadFull(f, payload); err != nil {
+			return nil, fmt.Errorf("failed to read section %d payload: %v", i, err)
+		}
+		sections[sectionType] = payload
+	}
+
+	if _, ok := sections[ptauSectionHeader]; !ok {
+		return nil, fmt.Errorf("ptau file is missing its header section")
+	}
+	return sections, nil
+}
+
+// parsePtauHeader decodes the header section: n8 (bytes per field element),
+// the field prime (n8 bytes, little-endian), and power (domain = 2^power).
+func parsePtauHeader(data []byte) (n8 uint32, prime *big.Int, power uint32, err error) {
+	if len(data) < 4 {
+		return 0, nil, 0, fmt.Errorf("header section too short")
+	}
+	n8 = binary.LittleEndian.Uint32(data[0:4])
+	if uint64(len(data)) < 4+uint64(n8)+4 {
+		return 0, nil, 0, fmt.Errorf("header section too short")
+	}
+	prime = new(big.Int).SetBytes(reverseBytes(data[4 : 4+n8]))
+	power = binary.LittleEndian.Uint32(data[4+n8 : 8+n8])
+	return n8, prime, power, nil
+}
+
+// parsePtauG1Points decodes the first count uncompressed (X, Y) G1 points
+// (each coordinate n8 little-endian bytes) from a tauG1-shaped section.
+func parsePtauG1Points(data []byte, n8 uint32, count uint64) ([]bn254.G1Affine, error) {
+	pointSize := uint64(2 * n8)
+	if uint64(len(data)) < pointSize*count {
+		return nil, fmt.Errorf("section has %d points, need at least %d", uint64(len(data))/pointSize, count)
+	}
+	points := make([]bn254.G1Affine, count)
+	for i := range points {
+		off := pointSize * uint64(i)
+		points[i].X = fpElementFromLE(data[off : off+uint64(n8)])
+		points[i].Y = fpElementFromLE(data[off+uint64(n8) : off+2*uint64(n8)])
+	}
+	return points, nil
+}
+
+// parsePtauG2Points decodes the first count uncompressed G2 points from a
+// tauG2-shaped section. Each G2 point is two Fp2 coordinates (X then Y),
+// each Fp2 coordinate two n8-byte Fp limbs (A0 then A1).
+func parsePtauG2Points(data []byte, n8 uint32, count uint64) ([]bn254.G2Affine, error) {
+	limbSize := uint64(n8)
+	pointSize := 4 * limbSize
+	if uint64(len(data)) < pointSize*count {
+		return nil, fmt.Errorf("section has %d points, need at least %d", uint64(len(data))/pointSize, count)
+	}
+	points := make([]bn254.G2Affine, count)
+	for i := range points {
+		off := pointSize * uint64(i)
+		limb := func(k uint64) fp.Element {
+			return fpElementFromLE(data[off+k*limbSize : off+(k+1)*limbSize])
+		}
+		points[i].X.A0, points[i].X.A1 = limb(0), limb(1)
+		points[i].Y.A0, points[i].Y.A1 = limb(2), limb(3)
+	}
+	return points, nil
+}
+
+func fpElementFromLE(b []byte) fp.Element {
+	var e fp.Element
+	e.SetBytes(reverseBytes(b))
+	return e
+}
+
+func reverseBytes(b []byte) []byte {
+	out := make([]byte, len(b))
+	for i, v := range b {
+		out[len(b)-1-i] = v
+	}
+	return out
+}
+
+// ptauFileHash returns the hex-encoded sha256 of a ptau file's contents, used
+// to tag pk-cache-dir entries with which ceremony file produced them (see
+// srsProvenance in cache.go).
+func ptauFileHash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open ptau file: %v", err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to hash ptau file: %v", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// srsFromConcrete round-trips a curve-specific SRS through its own
+// WriteTo/ReadFrom so it comes back as the curve-generic kzg.SRS our
+// wrapper otherwise plumbs around (see ProverInput's SRS/SRSLagrange
+// fields, which already carry gnark's native serialized SRS bytes).
+func srsFromConcrete(curveID ecc.ID, concrete interface {
+	io.WriterTo
+}) (kzg.SRS, error) {
+	var buf bytes.Buffer
+	if _, err := concrete.WriteTo(&buf); err != nil {
+		return nil, fmt.Errorf("failed to serialize SRS: %v", err)
+	}
+	srs := kzg.NewSRS(curveID)
+	if _, err := srs.ReadFrom(&buf); err != nil {
+		return nil, fmt.Errorf("failed to reload serialized SRS: %v", err)
+	}
+	return srs, nil
+}
+
+// ptauMinDomainSize is the domain size plonk.Setup needs for r1cs, matching
+// the sizing plonk itself uses internally.
+func ptauMinDomainSize(r1cs constraint.ConstraintSystem) uint64 {
+	return ecc.NextPowerOfTwo(uint64(r1cs.GetNbConstraints() + r1cs.GetNbPublicVariables()))
+}
+
+// tauG1ToLagrangeG1 converts domain-many monomial-basis powers of tau in G1
+// into the Lagrange basis over domain, via an inverse FFT: the same
+// Cooley-Tukey butterfly network used for field elements, with point
+// addition standing in for field addition and scalar multiplication by the
+// (inverse) roots of unity standing in for field multiplication.
+func tauG1ToLagrangeG1(tauG1 []bn254.G1Affine, domain *fft.Domain) ([]bn254.G1Affine, error) {
+	n := len(tauG1)
+	if n == 0 || n&(n-1) != 0 {
+		return nil, fmt.Errorf("domain size %d is not a power of two", n)
+	}
+
+	pts := make([]bn254.G1Jac, n)
+	for i := range pts {
+		pts[i].FromAffine(&tauG1[i])
+	}
+
+	logN := bits.Len(uint(n)) - 1
+	for i := 0; i < n; i++ {
+		j := bitReverse(i, logN)
+		if j > i {
+			pts[i], pts[j] = pts[j], pts[i]
+		}
+	}
+
+	twiddles := make([]big.Int, n/2)
+	w := domain.GeneratorInv
+	acc := new(big.Int).SetInt64(1)
+	wBig := new(big.Int)
+	w.BigInt(wBig)
+	for k := range twiddles {
+		twiddles[k].Set(acc)
+		acc.Mul(acc, wBig)
+		acc.Mod(acc, curveOrderBN254Fr())
+	}
+
+	for size := 2; size <= n; size <<= 1 {
+		half := size / 2
+		step := n / size
+		for start := 0; start < n; start += size {
+			for k := 0; k < half; k++ {
+				var t bn254.G1Jac
+				t.ScalarMultiplication(&pts[start+k+half], &twiddles[k*step])
+
+				var even bn254.G1Jac
+				even.Set(&pts[start+k])
+
+				pts[start+k].Set(&even).AddAssign(&t)
+				pts[start+k+half].Set(&even).SubAssign(&t)
+			}
+		}
+	}
+
+	cardInv := new(big.Int)
+	domain.CardinalityInv.BigInt(cardInv)
+	out := make([]bn254.G1Affine, n)
+	for i := range pts {
+		pts[i].ScalarMultiplication(&pts[i], cardInv)
+		out[i].FromJacobian(&pts[i])
+	}
+
+	return out, nil
+}
+
+func bitReverse(x, bitLen int) int {
+	r := 0
+	for i := 0; i < bitLen; i++ {
+		r = (r << 1) | (x & 1)
+		x >>= 1
+	}
+	return r
+}
+
+// curveOrderBN254Fr returns the bn254 scalar field modulus, used to reduce
+// twiddle-factor powers computed with plain big.Int arithmetic above.
+func curveOrderBN254Fr() *big.Int {
+	return ecc.BN254.ScalarField()
+}
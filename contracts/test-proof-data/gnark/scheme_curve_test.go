@@ -0,0 +1,111 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/test/unsafekzg"
+)
+
+// trivialCircuit asserts X == Y*Y: just enough of a circuit -- one public
+// and one secret variable -- to exercise compile/setup/prove/verify for
+// every (scheme, curve) pair in supportedSchemeCurves.
+type trivialCircuit struct {
+	X frontend.Variable `gnark:",public"`
+	Y frontend.Variable
+}
+
+func (c *trivialCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(c.X, api.Mul(c.Y, c.Y))
+	return nil
+}
+
+// TestProveVerifyEverySchemeCurvePair compiles, proves, and verifies
+// trivialCircuit for every pair supportedSchemeCurves advertises, so the
+// matrix validateSchemeCurve enforces stays backed by an actual proof
+// rather than just a lookup table.
+func TestProveVerifyEverySchemeCurvePair(t *testing.T) {
+	for scheme, curves := range supportedSchemeCurves {
+		for curveName, supported := range curves {
+			if !supported {
+				continue
+			}
+			scheme, curveName := scheme, curveName
+			t.Run(scheme+"/"+curveName, func(t *testing.T) {
+				curveID, err := parseCurve(curveName)
+				if err != nil {
+					t.Fatalf("parseCurve: %v", err)
+				}
+
+				assignment := &trivialCircuit{X: 9, Y: 3}
+
+				switch scheme {
+				case "groth16":
+					ccs, err := frontend.Compile(curveID.ScalarField(), r1cs.NewBuilder, &trivialCircuit{})
+					if err != nil {
+						t.Fatalf("Compile: %v", err)
+					}
+
+					fullWitness, err := frontend.NewWitness(assignment, curveID.ScalarField())
+					if err != nil {
+						t.Fatalf("NewWitness: %v", err)
+					}
+					publicWitness, err := fullWitness.Public()
+					if err != nil {
+						t.Fatalf("Public: %v", err)
+					}
+
+					pk, vk, err := groth16.Setup(ccs)
+					if err != nil {
+						t.Fatalf("Setup: %v", err)
+					}
+					proof, err := groth16.Prove(ccs, pk, fullWitness)
+					if err != nil {
+						t.Fatalf("Prove: %v", err)
+					}
+					if err := groth16.Verify(proof, vk, publicWitness); err != nil {
+						t.Fatalf("Verify: %v", err)
+					}
+
+				case "plonk":
+					ccs, err := frontend.Compile(curveID.ScalarField(), scs.NewBuilder, &trivialCircuit{})
+					if err != nil {
+						t.Fatalf("Compile: %v", err)
+					}
+
+					fullWitness, err := frontend.NewWitness(assignment, curveID.ScalarField())
+					if err != nil {
+						t.Fatalf("NewWitness: %v", err)
+					}
+					publicWitness, err := fullWitness.Public()
+					if err != nil {
+						t.Fatalf("Public: %v", err)
+					}
+
+					srs, srsLagrange, err := unsafekzg.NewSRS(ccs)
+					if err != nil {
+						t.Fatalf("unsafekzg.NewSRS: %v", err)
+					}
+					pk, vk, err := plonk.Setup(ccs, srs, srsLagrange)
+					if err != nil {
+						t.Fatalf("Setup: %v", err)
+					}
+					proof, err := plonk.Prove(ccs, pk, fullWitness)
+					if err != nil {
+						t.Fatalf("Prove: %v", err)
+					}
+					if err := plonk.Verify(proof, vk, publicWitness); err != nil {
+						t.Fatalf("Verify: %v", err)
+					}
+
+				default:
+					t.Fatalf("unexpected scheme %q", scheme)
+				}
+			})
+		}
+	}
+}
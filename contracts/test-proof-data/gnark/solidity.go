@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/solidity"
+	"golang.org/x/crypto/sha3"
+)
+
+// publicWitnessBinaryHeaderSize is the [nbPublic | nbSecret | n] uint32
+// header preceding a gnark binary witness (see witness.Witness.MarshalBinary).
+const publicWitnessBinaryHeaderSize = 12
+
+// fitsUint256 reports whether curveID's scalar field fits a Solidity
+// uint256, i.e. whether publicWitnessUint256's output is safe to use as
+// verifyProof(uint256[] calldata) argument. bw6-761's ~377-bit field does
+// not.
+func fitsUint256(curveID ecc.ID) bool {
+	return curveID.ScalarField().BitLen() <= 256
+}
+
+// publicWitnessUint256 decodes a serialized public witness into 0x-prefixed
+// uint256 hex strings, in wire order, for a generated verifier contract's
+// verifyProof(uint256[] calldata) argument. Only valid for curves whose
+// scalar field fits a uint256 (see fitsUint256); for a wider field it
+// returns an error.
+func publicWitnessUint256(curveID ecc.ID, data []byte) ([]string, error) {
+	if !fitsUint256(curveID) {
+		return nil, fmt.Errorf("curve %s's field is wider than 256 bits, its public inputs don't fit a uint256[] calldata argument", curveID.String())
+	}
+	if len(data) < publicWitnessBinaryHeaderSize {
+		return nil, fmt.Errorf("public witness buffer too small")
+	}
+
+	nbSecret := binary.BigEndian.Uint32(data[4:8])
+	if nbSecret != 0 {
+		return nil, fmt.Errorf("expected a public-only witness, got nbSecret=%d", nbSecret)
+	}
+	n := binary.BigEndian.Uint32(data[8:12])
+
+	elemSize := (curveID.ScalarField().BitLen() + 7) / 8
+	want := publicWitnessBinaryHeaderSize + int(n)*elemSize
+	if len(data) != want {
+		return nil, fmt.Errorf("public witness buffer size mismatch: got %d bytes, want %d", len(data), want)
+	}
+
+	out := make([]string, 0, n)
+	offset := publicWitnessBinaryHeaderSize
+	for i := uint32(0); i < n; i++ {
+		out = append(out, "0x"+hex.EncodeToString(data[offset:offset+elemSize]))
+		offset += elemSize
+	}
+
+	return out, nil
+}
+
+// solidityExporter is satisfied by both groth16.VerifyingKey and
+// plonk.VerifyingKey.
+type solidityExporter interface {
+	ExportSolidity(w io.Writer, opts ...solidity.ExportOption) error
+}
+
+// exportSolidityVerifier renders the Solidity verifier contract for vk,
+// using hashToFieldName to pick the Fiat-Shamir hash-to-field function so
+// the output matches whichever L2 verifier convention the caller targets.
+func exportSolidityVerifier(vk interface{}, hashToFieldName string) ([]byte, error) {
+	exporter, ok := vk.(solidityExporter)
+	if !ok {
+		return nil, fmt.Errorf("verifying key does not support Solidity export")
+	}
+
+	hFunc, err := hashToFieldFunc(hashToFieldName)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := exporter.ExportSolidity(&buf, solidity.WithHashToFieldFunction(hFunc)); err != nil {
+		return nil, fmt.Errorf("ExportSolidity error: %v", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func hashToFieldFunc(name string) (hash.Hash, error) {
+	switch name {
+	case "keccak256", "":
+		return sha3.NewLegacyKeccak256(), nil
+	case "sha256":
+		return sha256.New(), nil
+	default:
+		return nil, fmt.Errorf("unsupported hash-to-field function: %s (expected keccak256 or sha256)", name)
+	}
+}
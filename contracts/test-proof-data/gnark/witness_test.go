@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/witness"
+)
+
+// TestWitnessBinaryRoundTrip exercises the canonical witness_binary wire
+// format (see ProverInput.WitnessBinary) on every curve this tool supports:
+// fill a witness, marshal it to binary, run it through createWitness the
+// same way a real request would, and check the result marshals back to
+// identical bytes.
+func TestWitnessBinaryRoundTrip(t *testing.T) {
+	curves := []ecc.ID{ecc.BN254, ecc.BLS12_381, ecc.BLS12_377, ecc.BW6_761, ecc.BLS24_315}
+
+	for _, curveID := range curves {
+		curveID := curveID
+		t.Run(curveID.String(), func(t *testing.T) {
+			w, err := witness.New(curveID.ScalarField())
+			if err != nil {
+				t.Fatalf("witness.New: %v", err)
+			}
+
+			values := make(chan any, 3)
+			values <- 7
+			values <- 11
+			values <- 13
+			close(values)
+			if err := w.Fill(2, 1, values); err != nil {
+				t.Fatalf("Fill: %v", err)
+			}
+
+			bin, err := w.MarshalBinary()
+			if err != nil {
+				t.Fatalf("MarshalBinary: %v", err)
+			}
+
+			input := ProverInput{WitnessBinary: bin}
+			got, err := createWitness(input, curveID, nil, false)
+			if err != nil {
+				t.Fatalf("createWitness: %v", err)
+			}
+
+			gotBin, err := got.MarshalBinary()
+			if err != nil {
+				t.Fatalf("MarshalBinary (round-tripped): %v", err)
+			}
+			if string(gotBin) != string(bin) {
+				t.Fatalf("round-tripped witness does not match the original binary encoding")
+			}
+		})
+	}
+}